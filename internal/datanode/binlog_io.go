@@ -34,6 +34,7 @@ import (
 	"github.com/milvus-io/milvus/pkg/util/conc"
 	"github.com/milvus-io/milvus/pkg/util/merr"
 	"github.com/milvus-io/milvus/pkg/util/metautil"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
 	"github.com/milvus-io/milvus/pkg/util/retry"
 )
 
@@ -60,6 +61,89 @@ type uploader interface {
 type binlogIO struct {
 	storage.ChunkManager
 	allocator.Allocator
+
+	// downloadGate and uploadGates bound how many download/upload futures this
+	// binlogIO may have in flight at once, regardless of getMultiReadPool's size,
+	// so one segment with many field binlogs can't flood the pool or the
+	// backing object store with thousands of concurrent round trips.
+	downloadGate Gate
+	uploadGates  *gateGroup
+
+	// sidecarGates bounds checksum sidecar uploads independently of
+	// uploadGates. Sidecars ride along with every real upload, so sharing
+	// uploadGates with them would silently halve the concurrency budget
+	// dataNode.binlogIO.uploadConcurrency promises for actual segment data.
+	sidecarGates *gateGroup
+
+	// footerCache memoizes InsertCodecV2 footers fetched by downloadRows so
+	// repeated partial-range reads against the same binlog don't re-fetch it.
+	footerCache *footerCache
+
+	// coldTiers is an ordered list of ChunkManager tiers behind the embedded
+	// (hot) ChunkManager, e.g. the pre-existing S3/GCS/Azure object store
+	// passed to NewBinlogIO as a cold tier once a faster local-NVMe
+	// ChunkManager takes over as hot. download falls through to them in
+	// order on a hot miss; uploadSegmentFiles writes the hot tier
+	// synchronously and leaves mirroring to cold tiers to the background
+	// reconciler started by NewBinlogIO. coldTiers is nil, and every tiering
+	// field below unused, for a single-tier binlogIO (no cold tiers passed).
+	coldTiers  []storage.ChunkManager
+	tierPolicy TierPolicy
+	admission  *admissionFilter
+	dirty      *dirtyTracker
+
+	// hotLRU bounds hot-tier occupancy by dataNode.binlogIO.hotTierCapacityBytes
+	// once cold tiers exist to reclaim into; nil (no eviction) otherwise.
+	hotLRU *hotTierLRU
+}
+
+// NewBinlogIO builds a binlogIO whose hot tier is hot (read/written
+// synchronously by every upload/download, so it should be the fastest store
+// available, e.g. a local-NVMe ChunkManager) and whose optional cold tiers
+// are slower, larger ChunkManagers behind it (e.g. the S3/GCS/Azure object
+// store DataNode used before tiering existed). Download/upload fan-out
+// against hot is capped by dataNode.binlogIO.downloadConcurrency and
+// dataNode.binlogIO.uploadConcurrency (plus optional per-collection overrides
+// for uploads), independent of the shared IO pool's own size.
+//
+// Passing no cold tiers yields a plain single-tier binlogIO exactly as
+// before tiering existed. When cold is non-empty, NewBinlogIO also starts the
+// background tier reconciler and the hot-tier capacity reclaimer for the
+// lifetime of ctx, so a caller gets a fully tiered binlogIO without any
+// extra setup: writes mirror to cold storage, reads fall through to it on a
+// hot miss, a hot tier over dataNode.binlogIO.hotTierCapacityBytes evicts its
+// least-recently-used cold-durable objects, and DropBinlogs/IsColdDurable
+// reflect real reconciler state.
+func NewBinlogIO(ctx context.Context, hot storage.ChunkManager, alloc allocator.Allocator, cold ...storage.ChunkManager) *binlogIO {
+	params := paramtable.Get()
+	b := &binlogIO{
+		ChunkManager: hot,
+		Allocator:    alloc,
+		downloadGate: NewGate(params.DataNodeCfg.BinlogIODownloadConcurrency.GetAsInt()),
+		uploadGates:  newGateGroup("upload", params.DataNodeCfg.BinlogIOUploadConcurrency.GetAsInt()),
+		sidecarGates: newGateGroup("sidecar-upload", params.DataNodeCfg.BinlogIOSidecarUploadConcurrency.GetAsInt()),
+		footerCache:  newFooterCache(),
+	}
+
+	if len(cold) > 0 {
+		b.coldTiers = cold
+		b.tierPolicy = NewSizeTierPolicy(params.DataNodeCfg.BinlogIOColdTierMinMirrorBytes.GetAsInt64())
+		b.admission = newAdmissionFilter(0, 0)
+		b.dirty = newDirtyTracker()
+		b.hotLRU = newHotTierLRU(params.DataNodeCfg.BinlogIOHotTierCapacityBytes.GetAsInt64())
+		go b.RunTierReconciler(ctx, params.DataNodeCfg.BinlogIOColdTierReconcileInterval.GetAsDuration(time.Second))
+	}
+
+	return b
+}
+
+// binlogFormatV2Enabled reports whether dataNode.binlog.format is configured
+// as "v2". New flushes check this to decide whether to write the seekable,
+// chunk-indexed InsertCodecV2 layout instead of the monolithic v1 one; v1
+// objects remain readable regardless of this setting since download and
+// downloadRange dispatch on each object's own magic byte.
+func binlogFormatV2Enabled() bool {
+	return paramtable.Get().DataNodeCfg.BinlogFormatVersion.GetValue() == "v2"
 }
 
 var (
@@ -77,14 +161,40 @@ func (b *binlogIO) download(ctx context.Context, paths []string) ([]*Blob, error
 	for i, path := range paths {
 		localPath := path
 		future := getMultiReadPool().Submit(func() (any, error) {
+			begin := time.Now()
+			b.downloadGate.Start()
+			binlogIOGateWaitSeconds.WithLabelValues("download").Observe(time.Since(begin).Seconds())
+			defer b.downloadGate.Done()
+
 			var val []byte
 			var err error
 
 			log.Debug("binlogIO download", zap.String("path", localPath))
 			err = retry.Do(ctx, func() error {
-				val, err = b.Read(ctx, localPath)
+				if len(b.coldTiers) > 0 {
+					val, _, err = b.downloadFromTiers(ctx, localPath)
+				} else {
+					val, err = b.Read(ctx, localPath)
+				}
 				if err != nil {
 					log.Warn("binlogIO fail to download", zap.String("path", localPath), zap.Error(err))
+					return err
+				}
+				// A checksum mismatch means the object itself is corrupt, so
+				// looping through the retry policy again would never help; a
+				// transient error reading the sidecar itself is retried like
+				// any other download failure.
+				if err = b.verifyChecksum(ctx, localPath, val); err != nil {
+					log.Warn("binlogIO failed to verify checksum on download", zap.String("path", localPath), zap.Error(err))
+					return err
+				}
+
+				// Transparently unpack InsertCodecV2 objects so callers that
+				// only know the v1 layout keep working once flushes start
+				// producing v2; v1 objects pass through unchanged.
+				val, err = storage.DecodeInsertColumn(val)
+				if err != nil {
+					log.Warn("binlogIO failed to decode insert codec v2 blob", zap.String("path", localPath), zap.Error(err))
 				}
 				return err
 			}, retry.Attempts(3), retry.RetryErr(merr.IsRetryableErr))
@@ -114,37 +224,74 @@ func (b *binlogIO) uploadSegmentFiles(
 	if len(kvs) == 0 {
 		return nil
 	}
-	futures := make([]*conc.Future[any], 0)
+
+	// Embed a content digest alongside every blob so a corrupt object can be
+	// caught on the next download instead of silently propagating into query
+	// results. Sidecars go through their own gate (see sidecarGates) rather
+	// than uploadGates, so they can't eat into the real-data concurrency
+	// budget dataNode.binlogIO.uploadConcurrency promises operators.
+	sidecars := make(map[string][]byte, len(kvs))
 	for key, val := range kvs {
-		localPath := key
-		localVal := val
-		future := getMultiReadPool().Submit(func() (any, error) {
-			err := errStart
-			for err != nil {
-				select {
-				case <-ctx.Done():
-					log.Warn("ctx done when saving kvs to blob storage",
-						zap.Int64("collectionID", CollectionID),
-						zap.Int64("segmentID", segID),
-						zap.Int("number of kvs", len(kvs)))
-					return nil, errUploadToBlobStorage
-				default:
-					if err != errStart {
-						time.Sleep(50 * time.Millisecond)
-					}
-					err = b.Write(ctx, localPath, localVal)
-				}
-			}
-			return nil, nil
-		})
-		futures = append(futures, future)
+		sidecars[checksumPath(key)] = []byte(checksum(val))
 	}
 
-	err := conc.AwaitAll(futures...)
-	if err != nil {
-		return err
+	futures := make([]*conc.Future[any], 0, len(kvs)+len(sidecars))
+	for key, val := range kvs {
+		futures = append(futures, b.submitUpload(ctx, CollectionID, segID, key, val, b.uploadGates, false))
+	}
+	for key, val := range sidecars {
+		// A sidecar is only a few bytes, so sizeTierPolicy's minMirrorBytes
+		// threshold would almost never pass for it and verifyChecksum would
+		// then find it missing the moment the data it guards gets promoted
+		// from or evicted past the hot tier. Mirror it unconditionally so it
+		// is always exactly as durable as the object it checksums.
+		futures = append(futures, b.submitUpload(ctx, CollectionID, segID, key, val, b.sidecarGates, true))
 	}
-	return nil
+
+	return conc.AwaitAll(futures...)
+}
+
+// submitUpload writes one key/value to the hot tier through gates, blocking
+// only on that hot-tier write, then (for a tiered binlogIO) hands it to the
+// background reconciler for cold-tier mirroring. alwaysMirror skips
+// tierPolicy's own size threshold, for small sidecar objects that must track
+// the durability of the data they checksum regardless of size.
+func (b *binlogIO) submitUpload(ctx context.Context, collID, segID UniqueID, key string, val []byte, gates *gateGroup, alwaysMirror bool) *conc.Future[any] {
+	localPath := key
+	localVal := val
+	return getMultiReadPool().Submit(func() (any, error) {
+		gate := gates.start(collID)
+		defer gate.Done()
+
+		err := errStart
+		for err != nil {
+			select {
+			case <-ctx.Done():
+				log.Warn("ctx done when saving kvs to blob storage",
+					zap.Int64("collectionID", collID),
+					zap.Int64("segmentID", segID),
+					zap.String("path", localPath))
+				return nil, errUploadToBlobStorage
+			default:
+				if err != errStart {
+					time.Sleep(50 * time.Millisecond)
+				}
+				err = b.Write(ctx, localPath, localVal)
+			}
+		}
+
+		// The hot-tier write above is all uploadSegmentFiles waits on; a write
+		// the tier policy wants on cold storage too is handed to the background
+		// reconciler instead of blocking here on cold-tier durability.
+		if b.tierPolicy != nil {
+			logType := binlogTypeOf(localPath)
+			if alwaysMirror || b.tierPolicy.ShouldMirrorToCold(collID, logType, int64(len(localVal))) {
+				b.dirty.mark(localPath, dirtyEntry{collID: collID, logType: logType, value: localVal})
+			}
+		}
+		b.touchHotTier(ctx, localPath, int64(len(localVal)))
+		return nil, nil
+	})
 }
 
 // genDeltaBlobs returns key, value
@@ -167,7 +314,11 @@ func (b *binlogIO) genDeltaBlobs(data *DeleteData, collID, partID, segID UniqueI
 	return key, blob.GetValue(), nil
 }
 
-// genInsertBlobs returns insert-paths and save blob to kvs
+// genInsertBlobs returns insert-paths and save blob to kvs. When
+// dataNode.binlog.format is configured as "v2", each field's blob is
+// repackaged into the seekable, chunk-indexed InsertCodecV2 layout before
+// being staged in kvs; download/DecodeInsertColumn dispatch on the object's
+// own magic byte, so this is the only place v1 vs. v2 needs deciding.
 func (b *binlogIO) genInsertBlobs(data *InsertData, partID, segID UniqueID, iCodec *storage.InsertCodec, kvs map[string][]byte) (map[UniqueID]*datapb.FieldBinlog, error) {
 	inlogs, err := iCodec.Serialize(partID, segID, data)
 	if err != nil {
@@ -183,6 +334,10 @@ func (b *binlogIO) genInsertBlobs(data *InsertData, partID, segID UniqueID, iCod
 		return nil, err
 	}
 
+	useV2 := binlogFormatV2Enabled()
+	v2Codec := storage.NewInsertCodecV2WithSchema(iCodec.Schema)
+	pkField := primaryKeyField(iCodec.Schema)
+
 	for _, blob := range inlogs {
 		// Blob Key is generated by Serialize from int64 fieldID in collection schema, which won't raise error in ParseInt
 		fID, _ := strconv.ParseInt(blob.GetKey(), 10, 64)
@@ -190,6 +345,12 @@ func (b *binlogIO) genInsertBlobs(data *InsertData, partID, segID UniqueID, iCod
 		key := path.Join(b.ChunkManager.RootPath(), common.SegmentInsertLogPath, k)
 
 		value := blob.GetValue()
+		if useV2 {
+			value, err = v2Codec.SerializeColumn(fID, value, pkRangeFuncFor(pkField, fID))
+			if err != nil {
+				return nil, err
+			}
+		}
 		fileLen := len(value)
 
 		kvs[key] = value
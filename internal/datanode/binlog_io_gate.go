@@ -0,0 +1,128 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
+)
+
+// Gate bounds the number of in-flight operations that may hold it at once.
+// It is a buffered channel used purely for its blocking send/receive semantics:
+// Start blocks once the channel is full, Done frees a slot for the next waiter.
+type Gate chan struct{}
+
+// NewGate returns a Gate that admits at most n concurrent holders.
+func NewGate(n int) Gate {
+	if n <= 0 {
+		n = 1
+	}
+	return make(Gate, n)
+}
+
+// Start acquires a slot, blocking until one is available.
+func (g Gate) Start() {
+	g <- struct{}{}
+}
+
+// Done releases a slot acquired by Start.
+func (g Gate) Done() {
+	<-g
+}
+
+var binlogIOGateWaitSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "milvus",
+	Subsystem: "datanode",
+	Name:      "binlogio_gate_wait_seconds",
+	Help:      "time a binlogIO upload/download future spent waiting to acquire its concurrency gate",
+	Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+}, []string{"op"})
+
+// gateGroup wraps a default Gate with optional per-collection overrides, so a
+// handful of hot collections configured with their own limit cannot starve
+// every other collection's share of the shared default capacity.
+type gateGroup struct {
+	op  string
+	def Gate
+
+	mu        sync.RWMutex
+	overrides map[UniqueID]Gate
+}
+
+func newGateGroup(op string, defaultCapacity int) *gateGroup {
+	return &gateGroup{
+		op:        op,
+		def:       NewGate(defaultCapacity),
+		overrides: make(map[UniqueID]Gate),
+	}
+}
+
+// gateFor returns the gate collID should use, lazily creating a dedicated one
+// the first time a per-collection override is found configured for it.
+func (g *gateGroup) gateFor(collID UniqueID) Gate {
+	g.mu.RLock()
+	gate, ok := g.overrides[collID]
+	g.mu.RUnlock()
+	if ok {
+		return gate
+	}
+
+	capacity, ok := collectionGateOverride(collID)
+	if !ok {
+		return g.def
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if gate, ok := g.overrides[collID]; ok {
+		return gate
+	}
+	gate = NewGate(capacity)
+	g.overrides[collID] = gate
+	return gate
+}
+
+// start acquires a slot for collID from its gate and records how long the
+// caller waited for it, so operators can size concurrency from the histogram.
+func (g *gateGroup) start(collID UniqueID) Gate {
+	gate := g.gateFor(collID)
+	begin := time.Now()
+	gate.Start()
+	binlogIOGateWaitSeconds.WithLabelValues(g.op).Observe(time.Since(begin).Seconds())
+	return gate
+}
+
+// collectionGateOverride looks up a per-collection concurrency override from
+// dataNode.binlogIO.collectionConcurrencyOverride, keyed by collection ID.
+func collectionGateOverride(collID UniqueID) (int, bool) {
+	overrides := paramtable.Get().DataNodeCfg.BinlogIOCollectionConcurrencyOverride.GetAsMap()
+	raw, ok := overrides[strconv.FormatInt(collID, 10)]
+	if !ok {
+		return 0, false
+	}
+	capacity, err := strconv.Atoi(raw)
+	if err != nil || capacity <= 0 {
+		return 0, false
+	}
+	return capacity, true
+}
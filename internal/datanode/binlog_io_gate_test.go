@@ -0,0 +1,62 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGate_BoundsConcurrency(t *testing.T) {
+	g := NewGate(2)
+
+	g.Start()
+	g.Start()
+
+	started := make(chan struct{})
+	go func() {
+		g.Start()
+		close(started)
+	}()
+
+	select {
+	case <-started:
+		t.Fatal("third Start should have blocked while only 2 slots exist")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	g.Done()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Start should have unblocked after Done freed a slot")
+	}
+
+	g.Done()
+	g.Done()
+}
+
+func TestNewGate_NonPositiveDefaultsToOne(t *testing.T) {
+	g := NewGate(0)
+	assert.Equal(t, 1, cap(g))
+
+	g = NewGate(-5)
+	assert.Equal(t, 1, cap(g))
+}
@@ -0,0 +1,88 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"encoding/binary"
+
+	"github.com/milvus-io/milvus/internal/proto/etcdpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+)
+
+// primaryKeyField returns meta's primary key field, or nil if meta has none
+// (a malformed or not-yet-loaded collection schema).
+func primaryKeyField(meta *etcdpb.CollectionMeta) *schemapb.FieldSchema {
+	for _, f := range meta.GetSchema().GetFields() {
+		if f.GetIsPrimaryKey() {
+			return f
+		}
+	}
+	return nil
+}
+
+// pkRangeFuncFor returns the pkRange function genInsertBlobs should pass to
+// SerializeColumn for fID, or nil when row groups of that field can't carry a
+// useful PK range.
+//
+// RowGroupsForPK is only ever consulted against the PK field's own blob (a
+// predicate-pushdown caller already knows which field it's filtering on), so
+// only that field's row groups need a range; every other field's entries
+// simply have none and are always fetched, exactly as before this existed.
+// Within the PK field, only Int64 PKs (milvus's default auto-id type) get a
+// range: their rows are a fixed 8 bytes each, so a row-group's raw byte chunk
+// can be walked directly. A VarChar PK's rows vary in length, and this
+// snapshot's raw blob layout carries no per-row length index to map a byte
+// range back to whole rows, so it's left without a range rather than risking
+// a wrong one.
+func pkRangeFuncFor(pkField *schemapb.FieldSchema, fID int64) func(chunk []byte) (min, max []byte) {
+	if pkField == nil || fID != pkField.GetFieldID() {
+		return nil
+	}
+	if pkField.GetDataType() != schemapb.DataType_Int64 {
+		return nil
+	}
+	return int64PKRange
+}
+
+// int64PKRange reads chunk as packed little-endian int64 rows (the layout
+// InsertCodec uses for fixed-width numeric fields) and returns the min/max
+// value as big-endian bytes, so bytes.Compare against another big-endian
+// int64 agrees with numeric order for the non-negative auto-generated IDs
+// milvus's Int64 PK normally holds.
+func int64PKRange(chunk []byte) (min, max []byte) {
+	if len(chunk) < 8 || len(chunk)%8 != 0 {
+		return nil, nil
+	}
+
+	minV := int64(binary.LittleEndian.Uint64(chunk[0:8]))
+	maxV := minV
+	for off := 8; off < len(chunk); off += 8 {
+		v := int64(binary.LittleEndian.Uint64(chunk[off : off+8]))
+		if v < minV {
+			minV = v
+		}
+		if v > maxV {
+			maxV = v
+		}
+	}
+
+	minB := make([]byte, 8)
+	maxB := make([]byte, 8)
+	binary.BigEndian.PutUint64(minB, uint64(minV))
+	binary.BigEndian.PutUint64(maxB, uint64(maxV))
+	return minB, maxB
+}
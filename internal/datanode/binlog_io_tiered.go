@@ -0,0 +1,416 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// binlogTypeOf infers a kv path's log type from which segment sub-path it was
+// written under, since uploadSegmentFiles batches insert/stats/delta (and
+// checksum sidecar) writes together without threading the type through.
+func binlogTypeOf(path string) storage.BinlogType {
+	switch {
+	case strings.Contains(path, common.SegmentStatslogPath):
+		return storage.StatsBinlog
+	case strings.Contains(path, common.SegmentDeltaLogPath):
+		return storage.DeleteBinlog
+	default:
+		return storage.InsertBinlog
+	}
+}
+
+// TierPolicy decides whether a hot-tier write should also be mirrored to the
+// cold tier. binlogIO always writes the hot tier synchronously and returns as
+// soon as that succeeds; a write the policy selects for mirroring is handed
+// to the background reconciler instead of blocking uploadSegmentFiles on
+// cold-tier durability.
+type TierPolicy interface {
+	ShouldMirrorToCold(collID UniqueID, logType storage.BinlogType, size int64) bool
+}
+
+// sizeTierPolicy mirrors every write at or above minMirrorBytes to the cold
+// tier. Small, frequently-rewritten objects (e.g. repeatedly compacted delta
+// logs) skip the egress cost on every flush; large, rarely-touched insert
+// logs still get cold-tier durability.
+type sizeTierPolicy struct {
+	minMirrorBytes int64
+}
+
+// NewSizeTierPolicy returns a TierPolicy that mirrors writes of at least
+// minMirrorBytes to the cold tier.
+func NewSizeTierPolicy(minMirrorBytes int64) TierPolicy {
+	return sizeTierPolicy{minMirrorBytes: minMirrorBytes}
+}
+
+func (p sizeTierPolicy) ShouldMirrorToCold(_ UniqueID, _ storage.BinlogType, size int64) bool {
+	return size >= p.minMirrorBytes
+}
+
+var (
+	tierPromotionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "milvus",
+		Subsystem: "datanode",
+		Name:      "binlogio_tier_promotions_total",
+		Help:      "number of cold-tier reads promoted into the hot tier",
+	})
+	tierHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "milvus",
+		Subsystem: "datanode",
+		Name:      "binlogio_tier_hits_total",
+		Help:      "binlogIO reads served per tier",
+	}, []string{"tier"})
+	tierDirtyBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "milvus",
+		Subsystem: "datanode",
+		Name:      "binlogio_tier_dirty_bytes",
+		Help:      "bytes written to the hot tier that are not yet durable on the cold tier",
+	})
+)
+
+// admissionFilter decides whether a cold-tier hit is worth promoting into the
+// hot tier. Without it, a single pass over a segment by a one-shot compactor
+// would evict the hot tier's working set one object at a time; requiring a
+// path to be seen threshold times within the tracked window before
+// promotion keeps that kind of scan from polluting the cache.
+type admissionFilter struct {
+	mu        sync.Mutex
+	capacity  int
+	threshold int
+	counts    map[string]*list.Element
+	order     *list.List
+}
+
+type admissionEntry struct {
+	path  string
+	count int
+}
+
+func newAdmissionFilter(capacity, threshold int) *admissionFilter {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	if threshold <= 0 {
+		threshold = 2
+	}
+	return &admissionFilter{
+		capacity:  capacity,
+		threshold: threshold,
+		counts:    make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// observe records a cold-tier hit for path and reports whether it has now
+// been seen enough times to be promoted into the hot tier.
+func (f *admissionFilter) observe(path string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if el, ok := f.counts[path]; ok {
+		entry := el.Value.(*admissionEntry)
+		entry.count++
+		f.order.MoveToFront(el)
+		return entry.count >= f.threshold
+	}
+
+	if f.order.Len() >= f.capacity {
+		oldest := f.order.Back()
+		if oldest != nil {
+			f.order.Remove(oldest)
+			delete(f.counts, oldest.Value.(*admissionEntry).path)
+		}
+	}
+	el := f.order.PushFront(&admissionEntry{path: path, count: 1})
+	f.counts[path] = el
+	return f.threshold <= 1
+}
+
+// dirtyEntry is one hot-tier write awaiting confirmation that it has also
+// landed on the cold tier.
+type dirtyEntry struct {
+	collID  UniqueID
+	logType storage.BinlogType
+	value   []byte
+}
+
+// dirtyTracker holds hot-tier writes the reconciler still needs to mirror to
+// the cold tier, so a segment can be blocked from dropping its hot-tier copy
+// until its writes are confirmed durable there.
+type dirtyTracker struct {
+	mu      sync.Mutex
+	entries map[string]dirtyEntry
+	bytes   int64
+}
+
+func newDirtyTracker() *dirtyTracker {
+	return &dirtyTracker{entries: make(map[string]dirtyEntry)}
+}
+
+func (t *dirtyTracker) mark(path string, entry dirtyEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[path] = entry
+	t.bytes += int64(len(entry.value))
+	tierDirtyBytes.Set(float64(t.bytes))
+}
+
+func (t *dirtyTracker) clear(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if entry, ok := t.entries[path]; ok {
+		t.bytes -= int64(len(entry.value))
+		delete(t.entries, path)
+		tierDirtyBytes.Set(float64(t.bytes))
+	}
+}
+
+func (t *dirtyTracker) snapshot() map[string]dirtyEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]dirtyEntry, len(t.entries))
+	for k, v := range t.entries {
+		out[k] = v
+	}
+	return out
+}
+
+// DropBinlogs removes paths, and each one's checksum sidecar, from the hot
+// tier. It refuses to drop any path that is not yet confirmed durable on the
+// cold tier so a crash right after the drop can't lose the only copy of a
+// still-dirty write.
+func (b *binlogIO) DropBinlogs(ctx context.Context, paths []string) error {
+	if !b.IsColdDurable(paths) {
+		return errors.New("binlogIO: refusing to drop binlogs not yet durable on the cold tier")
+	}
+	for _, p := range paths {
+		if err := b.Remove(ctx, p); err != nil {
+			return err
+		}
+		// checksumPath(p) may not exist for binlogs written before checksums
+		// were introduced; ErrIoKeyNotFound there is not an error worth
+		// failing the drop over.
+		if err := b.Remove(ctx, checksumPath(p)); err != nil && !errors.Is(err, merr.ErrIoKeyNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsColdDurable reports whether every path has already been confirmed
+// mirrored to the cold tier. A segment must not be dropped from the hot tier
+// (e.g. a local-SSD tier being reclaimed) until this is true for all of its
+// binlog paths.
+func (b *binlogIO) IsColdDurable(paths []string) bool {
+	if b.dirty == nil {
+		return true
+	}
+	dirty := b.dirty.snapshot()
+	for _, p := range paths {
+		if _, ok := dirty[p]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mirrorToCold writes entry to every configured cold tier, in order, stopping
+// at the first error so the reconciler retries the remaining tiers next tick.
+func (b *binlogIO) mirrorToCold(ctx context.Context, path string, entry dirtyEntry) error {
+	for _, tier := range b.coldTiers {
+		if err := tier.Write(ctx, path, entry.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunTierReconciler periodically flushes dirty hot-tier objects down to the
+// cold tier until ctx is canceled. It is the write-through half of the hot
+// cache: uploadSegmentFiles only blocks on the hot-tier write, and this loop
+// catches the cold tier up in the background.
+func (b *binlogIO) RunTierReconciler(ctx context.Context, interval time.Duration) {
+	if len(b.coldTiers) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for path, entry := range b.dirty.snapshot() {
+				if err := b.mirrorToCold(ctx, path, entry); err != nil {
+					log.Warn("binlogIO tier reconciler failed to mirror to cold tier",
+						zap.String("path", path), zap.Error(err))
+					continue
+				}
+				b.dirty.clear(path)
+			}
+		}
+	}
+}
+
+// downloadFromTiers tries the hot tier first, then each cold tier in order,
+// promoting a cold hit back into the hot tier once the admission filter
+// judges it worth caching. Returns the value and which tier served it. On a
+// miss in every tier the error is a not-found only if no tier failed for
+// another reason.
+func (b *binlogIO) downloadFromTiers(ctx context.Context, path string) ([]byte, string, error) {
+	val, err := b.Read(ctx, path)
+	if err == nil {
+		tierHitsTotal.WithLabelValues("hot").Inc()
+		b.touchHotTier(ctx, path, int64(len(val)))
+		return val, "hot", nil
+	}
+
+	for i, tier := range b.coldTiers {
+		val, tierErr := tier.Read(ctx, path)
+		if tierErr != nil {
+			// Keep the first error that is not a plain miss, so a transient
+			// cold-tier failure is not reported as the object being absent.
+			if errors.Is(err, merr.ErrIoKeyNotFound) {
+				err = tierErr
+			}
+			continue
+		}
+		tierName := "cold"
+		tierHitsTotal.WithLabelValues(tierName).Inc()
+
+		if b.admission.observe(path) {
+			if werr := b.Write(ctx, path, val); werr != nil {
+				log.Warn("binlogIO failed to promote cold-tier hit into hot tier",
+					zap.String("path", path), zap.Int("coldTierIdx", i), zap.Error(werr))
+			} else {
+				tierPromotionsTotal.Inc()
+				b.touchHotTier(ctx, path, int64(len(val)))
+			}
+		}
+		return val, tierName, nil
+	}
+
+	return nil, "", err
+}
+
+// hotTierLRU tracks approximate hot-tier occupancy and reports which paths
+// to reclaim once capacityBytes is exceeded. It is the reclamation half of
+// the tiered cache that RunTierReconciler's write-through alone doesn't
+// provide: without it, a bounded local hot tier just fills up forever.
+type hotTierLRU struct {
+	mu            sync.Mutex
+	capacityBytes int64
+	usedBytes     int64
+	order         *list.List
+	index         map[string]*list.Element
+}
+
+type hotTierEntry struct {
+	path string
+	size int64
+}
+
+// newHotTierLRU returns a hotTierLRU that starts evicting once usedBytes
+// would exceed capacityBytes. capacityBytes <= 0 disables eviction (an
+// unbounded hot tier), matching the case where
+// dataNode.binlogIO.hotTierCapacityBytes is not configured.
+func newHotTierLRU(capacityBytes int64) *hotTierLRU {
+	return &hotTierLRU{
+		capacityBytes: capacityBytes,
+		order:         list.New(),
+		index:         make(map[string]*list.Element),
+	}
+}
+
+// touch records path as just written to or read from the hot tier and
+// returns the paths that should now be evicted to stay within capacityBytes.
+// isEvictable(path) is consulted before reclaiming anything the dirty
+// tracker still considers not yet mirrored to cold, so eviction can never
+// drop the only copy of an unmirrored write; an all-dirty hot tier is left
+// over capacity rather than losing data.
+func (l *hotTierLRU) touch(path string, size int64, isEvictable func(string) bool) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.index[path]; ok {
+		entry := el.Value.(*hotTierEntry)
+		l.usedBytes += size - entry.size
+		entry.size = size
+		l.order.MoveToFront(el)
+	} else {
+		el := l.order.PushFront(&hotTierEntry{path: path, size: size})
+		l.index[path] = el
+		l.usedBytes += size
+	}
+
+	if l.capacityBytes <= 0 {
+		return nil
+	}
+
+	var evicted []string
+	for attempts := l.order.Len(); l.usedBytes > l.capacityBytes && attempts > 0; attempts-- {
+		el := l.order.Back()
+		if el == nil {
+			break
+		}
+		entry := el.Value.(*hotTierEntry)
+		if !isEvictable(entry.path) {
+			l.order.MoveToFront(el)
+			continue
+		}
+		l.order.Remove(el)
+		delete(l.index, entry.path)
+		l.usedBytes -= entry.size
+		evicted = append(evicted, entry.path)
+	}
+	return evicted
+}
+
+// touchHotTier records a hot-tier write/read/promotion against b.hotLRU (a
+// no-op when no capacity limit is configured) and removes whatever it
+// reports as evictable.
+func (b *binlogIO) touchHotTier(ctx context.Context, path string, size int64) {
+	if b.hotLRU == nil {
+		return
+	}
+	for _, evictPath := range b.hotLRU.touch(path, size, func(p string) bool { return b.IsColdDurable([]string{p}) }) {
+		if err := b.Remove(ctx, evictPath); err != nil {
+			log.Warn("binlogIO failed to evict reclaimed hot-tier object",
+				zap.String("path", evictPath), zap.Error(err))
+			continue
+		}
+		if err := b.Remove(ctx, checksumPath(evictPath)); err != nil && !errors.Is(err, merr.ErrIoKeyNotFound) {
+			log.Warn("binlogIO failed to evict reclaimed hot-tier checksum sidecar",
+				zap.String("path", evictPath), zap.Error(err))
+		}
+	}
+}
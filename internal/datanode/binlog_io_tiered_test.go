@@ -0,0 +1,75 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/storage"
+)
+
+func TestAdmissionFilter_PromotesAfterThreshold(t *testing.T) {
+	f := newAdmissionFilter(10, 2)
+
+	assert.False(t, f.observe("a"))
+	assert.True(t, f.observe("a"))
+	// Already promoted once; continuing to observe it keeps reporting true.
+	assert.True(t, f.observe("a"))
+}
+
+func TestAdmissionFilter_EvictsOldestPastCapacity(t *testing.T) {
+	f := newAdmissionFilter(2, 2)
+
+	f.observe("a")
+	f.observe("b")
+	// "a" should be evicted to make room for "c", so it resets back to count 1.
+	f.observe("c")
+
+	assert.False(t, f.observe("a"))
+}
+
+func TestDirtyTracker_MarkClearSnapshot(t *testing.T) {
+	tr := newDirtyTracker()
+
+	tr.mark("p1", dirtyEntry{collID: 1, logType: storage.InsertBinlog, value: []byte("hello")})
+	tr.mark("p2", dirtyEntry{collID: 1, logType: storage.InsertBinlog, value: []byte("world!")})
+
+	snap := tr.snapshot()
+	assert.Len(t, snap, 2)
+	assert.Equal(t, []byte("hello"), snap["p1"].value)
+
+	tr.clear("p1")
+	snap = tr.snapshot()
+	assert.Len(t, snap, 1)
+	_, ok := snap["p1"]
+	assert.False(t, ok)
+}
+
+func TestBinlogIO_IsColdDurable(t *testing.T) {
+	b := &binlogIO{dirty: newDirtyTracker()}
+	b.dirty.mark("dirty-path", dirtyEntry{collID: 1, logType: storage.InsertBinlog, value: []byte("x")})
+
+	assert.False(t, b.IsColdDurable([]string{"dirty-path"}))
+	assert.True(t, b.IsColdDurable([]string{"clean-path"}))
+
+	// A binlogIO with no tiering configured (dirty is nil) treats everything
+	// as already durable, matching pre-tiering behavior.
+	b2 := &binlogIO{}
+	assert.True(t, b2.IsColdDurable([]string{"dirty-path"}))
+}
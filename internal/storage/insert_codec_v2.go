@@ -0,0 +1,269 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/crc32"
+	"io"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/milvus-io/milvus/internal/proto/etcdpb"
+)
+
+const (
+	// insertCodecV2Magic is the first byte of every InsertCodecV2 blob. A v1
+	// blob never starts with this byte, so dispatching between formats is a
+	// single-byte check and every binlog flushed before v2 existed keeps
+	// reading exactly as it did before.
+	insertCodecV2Magic byte = 0xB2
+
+	// defaultRowGroupBytes is the uncompressed size a column is chunked to
+	// before each chunk is compressed independently, matching the ~4MB target
+	// so one partial-range GET pulls back a reasonably sized, independently
+	// decompressible row group.
+	defaultRowGroupBytes = 4 << 20
+
+	// footerTrailerLen is the fixed-size trailer appended after the footer:
+	// a little-endian int64 byte offset pointing at where the footer itself
+	// begins, borrowed from the estargz seekable-archive trailing index idea.
+	footerTrailerLen = 8
+)
+
+// ByteRange is a half-open byte span [Offset, Offset+Length) within an
+// object, used to issue an HTTP Range / S3 partial GET instead of
+// downloading the whole object.
+type ByteRange struct {
+	Offset int64
+	Length int64
+}
+
+// RowGroupEntry is one row of an InsertCodecV2 footer's table of contents:
+// where a field's row group lives within the object, and the primary-key
+// range it covers so a predicate can skip it without downloading it.
+type RowGroupEntry struct {
+	FieldID       int64
+	RowGroupIdx   int32
+	Offset        int64
+	Length        int64
+	MinPK         []byte
+	MaxPK         []byte
+	CompressedCRC uint32
+}
+
+// InsertCodecV2Footer is the trailing table of contents over an
+// InsertCodecV2 blob's independently decompressible row groups.
+type InsertCodecV2Footer struct {
+	Entries []RowGroupEntry
+}
+
+// InsertCodecV2 serializes one InsertData column as a sequence of
+// independently compressed, fixed-size row groups followed by a footer TOC,
+// instead of InsertCodec's single monolithic blob per field. A caller that
+// only needs a PK subset (a compactor, the delete-applier, stats
+// regeneration) can fetch just the overlapping row groups through
+// binlogIO.downloadRows instead of paying for the whole column.
+type InsertCodecV2 struct {
+	Schema *etcdpb.CollectionMeta
+}
+
+// NewInsertCodecV2WithSchema returns an InsertCodecV2 bound to schema, the
+// same way NewInsertCodecWithSchema does for the v1 codec.
+func NewInsertCodecV2WithSchema(schema *etcdpb.CollectionMeta) *InsertCodecV2 {
+	return &InsertCodecV2{Schema: schema}
+}
+
+// SerializeColumn chunks raw into ~4MB row groups, gzip-compresses each, and
+// appends a footer TOC mapping row group index to its offset/length/CRC plus
+// the [minPK, maxPK] range pkRange reports for that chunk. pkRange may be
+// nil when the caller has no PK-range index to offer yet; its row groups
+// then carry no PK bounds and downloadRows must fetch them unconditionally.
+func (c *InsertCodecV2) SerializeColumn(fieldID int64, raw []byte, pkRange func(chunk []byte) (min, max []byte)) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(insertCodecV2Magic)
+
+	var footer InsertCodecV2Footer
+	for idx, start := 0, 0; start < len(raw) || (idx == 0 && len(raw) == 0); idx++ {
+		end := start + defaultRowGroupBytes
+		if end > len(raw) {
+			end = len(raw)
+		}
+		chunk := raw[start:end]
+
+		var compressed bytes.Buffer
+		gw := gzip.NewWriter(&compressed)
+		if _, err := gw.Write(chunk); err != nil {
+			return nil, err
+		}
+		if err := gw.Close(); err != nil {
+			return nil, err
+		}
+
+		entry := RowGroupEntry{
+			FieldID:       fieldID,
+			RowGroupIdx:   int32(idx),
+			Offset:        int64(buf.Len()),
+			Length:        int64(compressed.Len()),
+			CompressedCRC: crc32.Checksum(compressed.Bytes(), crc32.MakeTable(crc32.Castagnoli)),
+		}
+		if pkRange != nil {
+			entry.MinPK, entry.MaxPK = pkRange(chunk)
+		}
+		footer.Entries = append(footer.Entries, entry)
+
+		buf.Write(compressed.Bytes())
+		start = end
+		if len(raw) == 0 {
+			break
+		}
+	}
+
+	footerBytes, err := encodeFooter(&footer)
+	if err != nil {
+		return nil, err
+	}
+	footerOffset := int64(buf.Len())
+	buf.Write(footerBytes)
+	if err := binary.Write(&buf, binary.LittleEndian, footerOffset); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeFooter(f *InsertCodecV2Footer) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeFooter parses a footer previously written by encodeFooter.
+func DecodeFooter(raw []byte) (*InsertCodecV2Footer, error) {
+	var f InsertCodecV2Footer
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// IsInsertCodecV2 reports whether raw begins with the v2 magic byte.
+func IsInsertCodecV2(raw []byte) bool {
+	return len(raw) > 0 && raw[0] == insertCodecV2Magic
+}
+
+// FooterTrailerRange returns the byte range of the trailing offset pointer
+// within an InsertCodecV2 object of size objSize, so a caller can fetch just
+// that tiny range before deciding which row groups it actually needs.
+func FooterTrailerRange(objSize int64) ByteRange {
+	return ByteRange{Offset: objSize - footerTrailerLen, Length: footerTrailerLen}
+}
+
+// DecodeFooterOffset parses the trailing footerTrailerLen-byte little-endian
+// offset the footer itself is encoded at.
+func DecodeFooterOffset(trailer []byte) (int64, error) {
+	if len(trailer) != footerTrailerLen {
+		return 0, errors.Newf("insert codec v2: malformed footer trailer, want %d bytes got %d", footerTrailerLen, len(trailer))
+	}
+	return int64(binary.LittleEndian.Uint64(trailer)), nil
+}
+
+// DecodeInsertColumn reverses SerializeColumn, returning the original
+// uncompressed column bytes regardless of whether raw is a v1 blob (returned
+// unchanged, since it never starts with insertCodecV2Magic) or a v2 blob
+// (its row groups are decompressed and concatenated back in row-group
+// order). This lets download's existing v1-oriented callers keep working
+// unmodified once dataNode.binlog.format=v2 starts producing v2 objects.
+func DecodeInsertColumn(raw []byte) ([]byte, error) {
+	if !IsInsertCodecV2(raw) {
+		return raw, nil
+	}
+	if int64(len(raw)) < footerTrailerLen {
+		return nil, errors.New("insert codec v2: blob too small to contain a footer trailer")
+	}
+
+	trailer := raw[len(raw)-footerTrailerLen:]
+	footerOffset, err := DecodeFooterOffset(trailer)
+	if err != nil {
+		return nil, err
+	}
+	if footerOffset < 0 || footerOffset > int64(len(raw))-footerTrailerLen {
+		return nil, errors.Newf("insert codec v2: footer offset %d out of bounds", footerOffset)
+	}
+	footer, err := DecodeFooter(raw[footerOffset : int64(len(raw))-footerTrailerLen])
+	if err != nil {
+		return nil, err
+	}
+
+	entries := append([]RowGroupEntry(nil), footer.Entries...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].RowGroupIdx < entries[j].RowGroupIdx })
+
+	var out bytes.Buffer
+	for _, e := range entries {
+		if e.Offset < 0 || e.Offset+e.Length > int64(len(raw)) {
+			return nil, errors.Newf("insert codec v2: row group %d out of bounds", e.RowGroupIdx)
+		}
+		chunk, err := DecompressRowGroup(raw[e.Offset : e.Offset+e.Length])
+		if err != nil {
+			return nil, err
+		}
+		out.Write(chunk)
+	}
+	return out.Bytes(), nil
+}
+
+// DecompressRowGroup gunzips compressed, the bytes of a single row group as
+// written by SerializeColumn (one ByteRange's worth), for a caller that
+// fetched it directly via a ranged read instead of going through
+// DecodeInsertColumn against the whole object.
+func DecompressRowGroup(compressed []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, gr); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// RowGroupsForPK returns the subset of footer entries whose [MinPK, MaxPK]
+// range could contain pk, assuming PKs are encoded so byte-lexicographic
+// order matches PK order. Entries without a PK range are always included
+// since their rows can't be ruled out.
+func RowGroupsForPK(footer *InsertCodecV2Footer, pk []byte) []RowGroupEntry {
+	var out []RowGroupEntry
+	for _, e := range footer.Entries {
+		if e.MinPK == nil || e.MaxPK == nil {
+			out = append(out, e)
+			continue
+		}
+		if bytes.Compare(pk, e.MinPK) >= 0 && bytes.Compare(pk, e.MaxPK) <= 0 {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertCodecV2_SerializeColumnRoundTrip(t *testing.T) {
+	c := &InsertCodecV2{}
+	raw := bytes.Repeat([]byte("milvus-row-group-data"), 1000)
+
+	encoded, err := c.SerializeColumn(100, raw, nil)
+	require.NoError(t, err)
+	assert.True(t, IsInsertCodecV2(encoded))
+
+	decoded, err := DecodeInsertColumn(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, raw, decoded)
+}
+
+func TestInsertCodecV2_SerializeColumnWithPKRange(t *testing.T) {
+	c := &InsertCodecV2{}
+	raw := bytes.Repeat([]byte("x"), defaultRowGroupBytes*2+10)
+
+	calls := 0
+	pkRange := func(chunk []byte) ([]byte, []byte) {
+		calls++
+		return []byte{byte(calls)}, []byte{byte(calls + 1)}
+	}
+	encoded, err := c.SerializeColumn(5, raw, pkRange)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	footerOffset, err := DecodeFooterOffset(encoded[len(encoded)-footerTrailerLen:])
+	require.NoError(t, err)
+	footer, err := DecodeFooter(encoded[footerOffset : int64(len(encoded))-footerTrailerLen])
+	require.NoError(t, err)
+	require.Len(t, footer.Entries, 3)
+	assert.Equal(t, []byte{1}, footer.Entries[0].MinPK)
+	assert.Equal(t, []byte{3}, footer.Entries[2].MinPK)
+}
+
+func TestDecompressRowGroup(t *testing.T) {
+	c := &InsertCodecV2{}
+	raw := []byte("a single small row group")
+
+	encoded, err := c.SerializeColumn(1, raw, nil)
+	require.NoError(t, err)
+
+	footerOffset, err := DecodeFooterOffset(encoded[len(encoded)-footerTrailerLen:])
+	require.NoError(t, err)
+	footer, err := DecodeFooter(encoded[footerOffset : int64(len(encoded))-footerTrailerLen])
+	require.NoError(t, err)
+	require.Len(t, footer.Entries, 1)
+
+	e := footer.Entries[0]
+	chunk, err := DecompressRowGroup(encoded[e.Offset : e.Offset+e.Length])
+	require.NoError(t, err)
+	assert.Equal(t, raw, chunk)
+}
+
+func TestInsertCodecV2_DecodeInsertColumnPassesThroughV1(t *testing.T) {
+	v1 := []byte("not a v2 blob")
+
+	decoded, err := DecodeInsertColumn(v1)
+	require.NoError(t, err)
+	assert.Equal(t, v1, decoded)
+}
+
+func TestInsertCodecV2_EmptyColumnRoundTrip(t *testing.T) {
+	c := &InsertCodecV2{}
+
+	encoded, err := c.SerializeColumn(1, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, IsInsertCodecV2(encoded))
+
+	decoded, err := DecodeInsertColumn(encoded)
+	require.NoError(t, err)
+	assert.Empty(t, decoded)
+}
+
+func TestInsertCodecV2_FooterTrailerRoundTrip(t *testing.T) {
+	c := &InsertCodecV2{}
+	raw := bytes.Repeat([]byte("x"), defaultRowGroupBytes+1234)
+
+	pkRange := func(chunk []byte) ([]byte, []byte) {
+		return chunk[:1], chunk[len(chunk)-1:]
+	}
+	encoded, err := c.SerializeColumn(7, raw, pkRange)
+	require.NoError(t, err)
+
+	trailerRange := FooterTrailerRange(int64(len(encoded)))
+	trailer := encoded[trailerRange.Offset : trailerRange.Offset+trailerRange.Length]
+	footerOffset, err := DecodeFooterOffset(trailer)
+	require.NoError(t, err)
+
+	footer, err := DecodeFooter(encoded[footerOffset : int64(len(encoded))-footerTrailerLen])
+	require.NoError(t, err)
+	assert.Len(t, footer.Entries, 2)
+}
+
+func TestRowGroupsForPK(t *testing.T) {
+	footer := &InsertCodecV2Footer{
+		Entries: []RowGroupEntry{
+			{RowGroupIdx: 0, MinPK: []byte{0x00}, MaxPK: []byte{0x10}},
+			{RowGroupIdx: 1, MinPK: []byte{0x20}, MaxPK: []byte{0x30}},
+			{RowGroupIdx: 2}, // no PK range recorded, must always be included
+		},
+	}
+
+	matches := RowGroupsForPK(footer, []byte{0x05})
+	require.Len(t, matches, 2)
+	assert.Equal(t, int32(0), matches[0].RowGroupIdx)
+	assert.Equal(t, int32(2), matches[1].RowGroupIdx)
+
+	matches = RowGroupsForPK(footer, []byte{0xFF})
+	require.Len(t, matches, 1)
+	assert.Equal(t, int32(2), matches[0].RowGroupIdx)
+}